@@ -0,0 +1,176 @@
+package web_responders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+
+	"github.com/stretchr/objx"
+)
+
+var (
+	bindContextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	bindErrorType   = reflect.TypeOf((*error)(nil)).Elem()
+	bindOptionsType = reflect.TypeOf(objx.Map(nil))
+)
+
+// BindResponder is the http.Handler returned by Bind. It also exposes
+// the function the handler was built from, which is useful for
+// logging and for tests that want to call the function directly.
+type BindResponder interface {
+	http.Handler
+
+	// Func returns the function this responder was built from.
+	Func() interface{}
+}
+
+// Bind reflects fn into an http.Handler. fn's parameters may be any
+// permutation of context.Context, a pointer to a request-input struct,
+// and an objx.Map of options; its return values may be any permutation
+// of a response value and an error.
+//
+// The returned handler decodes the request's query string and JSON
+// body into the input struct, using Request.Decode with
+// WeaklyTypedInput enabled, invokes fn, and feeds the result into a
+// Response{Data: ret} so that all of the usual conversion machinery -
+// PreMarshaller, PostMarshaller, LazyLoader, Constructor/Fixer, and
+// ResponseElementConverter - still runs. An error returned by fn
+// becomes the Response's Data, so it flows through the same `error`
+// branch createOutput already has for top-level errors. A nil pointer
+// returned by fn serializes as null, the same as it would from any
+// other Response.
+//
+// Bind panics if fn's signature doesn't match one of the shapes above.
+// This is meant to be caught while registering routes, not at request
+// time.
+func Bind(fn interface{}) BindResponder {
+	fnValue := reflect.ValueOf(fn)
+	fnType := fnValue.Type()
+	if fnType.Kind() != reflect.Func {
+		panic(fmt.Sprintf("web_responders: Bind requires a function, got %s", fnType))
+	}
+
+	handler := &boundHandler{fn: fnValue, fnType: fnType, valueIndex: -1}
+
+	for i := 0; i < fnType.NumIn(); i++ {
+		in := fnType.In(i)
+		switch {
+		case in == bindContextType:
+			handler.contextIndex = i
+			handler.hasContext = true
+		case in == bindOptionsType:
+			handler.optionsIndex = i
+			handler.hasOptions = true
+		case in.Kind() == reflect.Ptr && in.Elem().Kind() == reflect.Struct:
+			if handler.inputType != nil {
+				panic("web_responders: Bind functions may only take one request-input parameter")
+			}
+			handler.inputType = in
+			handler.inputIndex = i
+		default:
+			panic(fmt.Sprintf("web_responders: Bind does not support parameter type %s", in))
+		}
+	}
+
+	for i := 0; i < fnType.NumOut(); i++ {
+		out := fnType.Out(i)
+		if out == bindErrorType {
+			handler.errorIndex = i
+			handler.hasError = true
+			continue
+		}
+		if handler.valueIndex >= 0 {
+			panic("web_responders: Bind functions may only return one non-error value")
+		}
+		handler.valueIndex = i
+	}
+
+	return handler
+}
+
+type boundHandler struct {
+	fn     reflect.Value
+	fnType reflect.Type
+
+	inputType  reflect.Type
+	inputIndex int
+
+	hasContext   bool
+	contextIndex int
+
+	hasOptions   bool
+	optionsIndex int
+
+	hasError   bool
+	errorIndex int
+
+	valueIndex int
+}
+
+func (handler *boundHandler) Func() interface{} {
+	return handler.fn.Interface()
+}
+
+func (handler *boundHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	options := make(objx.Map)
+
+	args := make([]reflect.Value, handler.fnType.NumIn())
+	if handler.hasContext {
+		args[handler.contextIndex] = reflect.ValueOf(r.Context())
+	}
+	if handler.hasOptions {
+		args[handler.optionsIndex] = reflect.ValueOf(options)
+	}
+	if handler.inputType != nil {
+		input := reflect.New(handler.inputType.Elem())
+		if err := decodeBoundInput(r, input.Interface()); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		args[handler.inputIndex] = input
+	}
+
+	results := handler.fn.Call(args)
+
+	var data interface{}
+	if handler.valueIndex >= 0 {
+		data = results[handler.valueIndex].Interface()
+	}
+	if handler.hasError {
+		if err, _ := results[handler.errorIndex].Interface().(error); err != nil {
+			data = err
+		}
+	}
+
+	response := &Response{Data: data, Options: options}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response.Output()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// decodeBoundInput merges a request's query string and JSON body into
+// a single map and decodes it into target using Request.Decode.
+func decodeBoundInput(r *http.Request, target interface{}) error {
+	if err := r.ParseForm(); err != nil {
+		return err
+	}
+	input := ParseQuery(r.Form)
+
+	if r.Body != nil {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			return err
+		}
+		for key, value := range body {
+			input[key] = value
+		}
+	}
+
+	request := &Request{WeaklyTypedInput: true}
+	return request.Decode(input, target)
+}
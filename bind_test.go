@@ -0,0 +1,89 @@
+package web_responders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type bindInput struct {
+	Name string `response:"name"`
+	Age  int    `response:"age"`
+}
+
+type bindOutput struct {
+	Greeting string `response:"greeting"`
+}
+
+func TestBindRoundTripsQueryAndJSONBody(t *testing.T) {
+	handler := Bind(func(ctx context.Context, in *bindInput) (*bindOutput, error) {
+		return &bindOutput{Greeting: fmt.Sprintf("hello %s, age %d", in.Name, in.Age)}, nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/?name=Ada", strings.NewReader(`{"age": 30}`))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON response: %v (body: %s)", err, rec.Body.String())
+	}
+	if out["greeting"] != "hello Ada, age 30" {
+		t.Errorf(`expected greeting "hello Ada, age 30", got %v`, out["greeting"])
+	}
+}
+
+func TestBindPanicsOnUnsupportedParameterType(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected Bind to panic on an unsupported parameter type")
+		}
+	}()
+	Bind(func(n int) error { return nil })
+}
+
+func TestBindPanicsOnMultipleInputParameters(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected Bind to panic when given more than one request-input parameter")
+		}
+	}()
+	Bind(func(a *bindInput, b *bindOutput) error { return nil })
+}
+
+func TestBindErrorSurfacesThroughResponse(t *testing.T) {
+	handler := Bind(func() (*bindOutput, error) {
+		return nil, errors.New("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var out string
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatalf("invalid JSON response: %v (body: %s)", err, rec.Body.String())
+	}
+	if out != "boom" {
+		t.Errorf(`expected the error's message to surface as the response body, got %q`, out)
+	}
+}
+
+func TestBindNilPointerReturnSerializesToNull(t *testing.T) {
+	handler := Bind(func() (*bindOutput, error) {
+		return nil, nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if strings.TrimSpace(rec.Body.String()) != "null" {
+		t.Errorf(`expected a nil pointer return to serialize as "null", got %q`, rec.Body.String())
+	}
+}
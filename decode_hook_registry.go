@@ -0,0 +1,155 @@
+package web_responders
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/stretchr/objx"
+)
+
+// DecodeHook customizes decoding of an input value into a target Go
+// type - the input-side analogue of Hook. It is passed the
+// reflect.Value to populate, the raw input value, and the Request's
+// Options, and should return (output, true, nil) if it handled the
+// conversion. Returning (nil, false, nil) leaves the input for later
+// hooks, or the standard decode logic, to handle. A non-nil error
+// means the hook recognized the input but couldn't convert it.
+type DecodeHook func(target reflect.Value, input interface{}, opts objx.Map) (interface{}, bool, error)
+
+// DecodeHookRegistry maps reflect.Types and reflect.Kinds to
+// DecodeHooks, the input-side analogue of HookRegistry.
+//
+// Request.Decode consults a DecodeHookRegistry before falling into its
+// standard decode logic for every value in the input. Type hooks are
+// tried before kind hooks, and hooks within each are tried in
+// registration order; the first hook to report success wins.
+type DecodeHookRegistry struct {
+	typeHooks map[reflect.Type][]DecodeHook
+	kindHooks map[reflect.Kind][]DecodeHook
+}
+
+// NewDecodeHookRegistry returns an empty DecodeHookRegistry, ready to
+// have hooks registered on it.
+func NewDecodeHookRegistry() *DecodeHookRegistry {
+	return &DecodeHookRegistry{
+		typeHooks: make(map[reflect.Type][]DecodeHook),
+		kindHooks: make(map[reflect.Kind][]DecodeHook),
+	}
+}
+
+// DefaultDecodeHooks is the package-level DecodeHookRegistry used by
+// any Request whose own HookRegistry field is nil.
+var DefaultDecodeHooks = NewDecodeHookRegistry()
+
+func init() {
+	DefaultDecodeHooks.RegisterType(reflect.TypeOf(time.Time{}), TimeDecodeHook)
+	DefaultDecodeHooks.RegisterKind(reflect.Slice, StringSliceDecodeHook)
+	DefaultDecodeHooks.RegisterKind(reflect.Struct, NullableDbDecodeHook)
+}
+
+// RegisterType adds hook as a DecodeHook for the exact type t. Type
+// hooks run before any hooks registered for t's Kind.
+func (registry *DecodeHookRegistry) RegisterType(t reflect.Type, hook DecodeHook) {
+	registry.typeHooks[t] = append(registry.typeHooks[t], hook)
+}
+
+// RegisterKind adds hook as a DecodeHook for the given reflect.Kind.
+// Kind hooks run after any hooks registered for the target's exact
+// type.
+func (registry *DecodeHookRegistry) RegisterKind(kind reflect.Kind, hook DecodeHook) {
+	registry.kindHooks[kind] = append(registry.kindHooks[kind], hook)
+}
+
+// run tries, in order, the type hooks registered for target's exact
+// type and then the kind hooks registered for target's Kind, returning
+// the output of the first hook that reports success.
+func (registry *DecodeHookRegistry) run(target reflect.Value, input interface{}, opts objx.Map) (interface{}, bool, error) {
+	if registry == nil || !target.IsValid() {
+		return nil, false, nil
+	}
+	if out, ok, err := ComposeDecodeHooks(registry.typeHooks[target.Type()]...)(target, input, opts); ok || err != nil {
+		return out, ok, err
+	}
+	return ComposeDecodeHooks(registry.kindHooks[target.Kind()]...)(target, input, opts)
+}
+
+// ComposeDecodeHooks returns a DecodeHook that tries each of the given
+// hooks in order, returning the output of the first one that reports
+// success or failure.
+func ComposeDecodeHooks(hooks ...DecodeHook) DecodeHook {
+	return func(target reflect.Value, input interface{}, opts objx.Map) (interface{}, bool, error) {
+		for _, hook := range hooks {
+			if out, ok, err := hook(target, input, opts); ok || err != nil {
+				return out, ok, err
+			}
+		}
+		return nil, false, nil
+	}
+}
+
+// TimeDecodeHook parses time.Time targets from RFC3339 strings, or
+// accepts a time.Time input as-is.
+func TimeDecodeHook(target reflect.Value, input interface{}, opts objx.Map) (interface{}, bool, error) {
+	if target.Type() != reflect.TypeOf(time.Time{}) {
+		return nil, false, nil
+	}
+	switch v := input.(type) {
+	case time.Time:
+		return v, true, nil
+	case string:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, true, err
+		}
+		return t, true, nil
+	}
+	return nil, false, nil
+}
+
+// StringSliceDecodeHook decodes a comma-separated string into a
+// []string, the common shape of a repeated query parameter that
+// arrived as a single value.
+func StringSliceDecodeHook(target reflect.Value, input interface{}, opts objx.Map) (interface{}, bool, error) {
+	if target.Type() != reflect.TypeOf([]string(nil)) {
+		return nil, false, nil
+	}
+	s, ok := input.(string)
+	if !ok {
+		return nil, false, nil
+	}
+	if s == "" {
+		return []string{}, true, nil
+	}
+	return strings.Split(s, ","), true, nil
+}
+
+// NullableDbDecodeHook constructs "database/sql".Null* types, and any
+// other type matching that structure, from a plain input value - the
+// decode-side counterpart to NullableDbHook.
+func NullableDbDecodeHook(target reflect.Value, input interface{}, opts objx.Map) (interface{}, bool, error) {
+	typeName := target.Type().Name()
+	if !strings.HasPrefix(typeName, SqlNullablePrefix) {
+		return nil, false, nil
+	}
+	fieldName := typeName[len(SqlNullablePrefix):]
+	valField := target.FieldByName(fieldName)
+	validField := target.FieldByName("Valid")
+	if !valField.IsValid() || !validField.IsValid() {
+		return nil, false, nil
+	}
+
+	result := reflect.New(target.Type()).Elem()
+	if input == nil {
+		return result.Interface(), true, nil
+	}
+
+	inputValue := reflect.ValueOf(input)
+	if !inputValue.Type().ConvertibleTo(valField.Type()) {
+		return nil, true, fmt.Errorf("web_responders: cannot decode %T into %s", input, valField.Type())
+	}
+	result.FieldByName(fieldName).Set(inputValue.Convert(valField.Type()))
+	result.FieldByName("Valid").SetBool(true)
+	return result.Interface(), true, nil
+}
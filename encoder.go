@@ -0,0 +1,259 @@
+package web_responders
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/stretchr/objx"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Encoder produces the wire representation of a Response's generated
+// output for a specific content type. JSONEncoder, XMLEncoder, and
+// MsgpackEncoder all build on the same Response.Output() traversal -
+// tag lookup, embedded flattening, sql.Null* unwrapping, converter
+// dispatch, and cond/ShouldInclude filtering all happen exactly once,
+// so a type's tags and converter interfaces drive every format
+// identically. See Output() for the tradeoff that comes with sharing
+// its tree across formats instead of each Encoder writing straight off
+// the reflect walk. xmlAttr is the one piece of per-format information
+// carried on that tree, so that XMLEncoder can place a field as an
+// attribute without a second walk of the original struct, and
+// MsgpackEncoder can unwrap it via CustomEncoder without one either.
+type Encoder interface {
+	// ContentType returns the MIME type this Encoder produces. It is
+	// used both to set the response's Content-Type header and to
+	// match against an incoming Accept header in ResponseWriter.
+	ContentType() string
+
+	// Encode returns the wire representation of resp.Output().
+	Encode(resp *Response) ([]byte, error)
+}
+
+// xmlAttr marks a struct field's generated value as an XML attribute
+// of its parent element, per an `xml:"name,attr"` tag alongside the
+// usual response/json tag. It is transparent to every Encoder besides
+// XMLEncoder: MarshalJSON and EncodeMsgpack both pass straight through
+// to the wrapped value, so JSON and MessagePack output are unaffected
+// by the presence of an xml tag.
+type xmlAttr struct {
+	Value interface{}
+}
+
+func (a xmlAttr) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Value)
+}
+
+// EncodeMsgpack mirrors MarshalJSON: it lets msgpack.Marshal unwrap an
+// xmlAttr in place as it walks Output()'s tree, the same way
+// encoding/json does, so MsgpackEncoder never needs a second pass over
+// the tree to strip xmlAttr wrappers out first.
+func (a xmlAttr) EncodeMsgpack(enc *msgpack.Encoder) error {
+	return enc.Encode(a.Value)
+}
+
+// isXMLAttr reports whether fieldType's "xml" tag marks it as an
+// attribute, e.g. `xml:"id,attr"`.
+func isXMLAttr(fieldType reflect.StructField) bool {
+	tag := fieldType.Tag.Get("xml")
+	if tag == "" {
+		return false
+	}
+	for _, part := range strings.Split(tag, ",")[1:] {
+		if part == "attr" {
+			return true
+		}
+	}
+	return false
+}
+
+// JSONEncoder encodes a Response's output as JSON. It is the encoding
+// this package has always produced.
+type JSONEncoder struct{}
+
+func (JSONEncoder) ContentType() string {
+	return "application/json"
+}
+
+func (JSONEncoder) Encode(resp *Response) ([]byte, error) {
+	return json.Marshal(resp.Output())
+}
+
+// MsgpackEncoder encodes a Response's output as MessagePack.
+type MsgpackEncoder struct{}
+
+func (MsgpackEncoder) ContentType() string {
+	return "application/msgpack"
+}
+
+func (MsgpackEncoder) Encode(resp *Response) ([]byte, error) {
+	return msgpack.Marshal(resp.Output())
+}
+
+// XMLEncoder encodes a Response's output as XML. Of a field's `xml`
+// tag, only the ",attr" option is read: fields tagged
+// `xml:"name,attr"` are written as attributes of their parent element
+// (AWS REST-XML builder style); every other field is written as a
+// nested element. Attribute and element names both come from the
+// field's response/json tag, or its lowercased field name; the name
+// part of the xml tag is not used.
+type XMLEncoder struct {
+	// Root is the element name used for the top-level value. It
+	// defaults to "response" if empty.
+	Root string
+
+	// Item is the element name used for each item when the top-level
+	// value is a list, since a bare list has no field name of its own
+	// to draw one from. It defaults to "item" if empty. Root still
+	// applies in this case, as the single enclosing element the list
+	// is written into - XML requires exactly one root element, so a
+	// top-level list can't be written as repeated sibling elements the
+	// way a nested list field is.
+	Item string
+}
+
+func (XMLEncoder) ContentType() string {
+	return "application/xml"
+}
+
+func (encoder XMLEncoder) Encode(resp *Response) ([]byte, error) {
+	root := encoder.Root
+	if root == "" {
+		root = "response"
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+
+	output := resp.Output()
+	if list, ok := output.([]interface{}); ok {
+		item := encoder.Item
+		if item == "" {
+			item = "item"
+		}
+		fmt.Fprintf(&buf, "<%s>", root)
+		for _, element := range list {
+			if err := writeXMLElement(&buf, item, element); err != nil {
+				return nil, err
+			}
+		}
+		fmt.Fprintf(&buf, "</%s>", root)
+		return buf.Bytes(), nil
+	}
+
+	if err := writeXMLElement(&buf, root, output); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeXMLElement(buf *bytes.Buffer, name string, data interface{}) error {
+	switch v := data.(type) {
+	case xmlAttr:
+		return writeXMLElement(buf, name, v.Value)
+	case objx.Map:
+		attrs, children := splitXMLFields(v)
+		fmt.Fprintf(buf, "<%s", name)
+		for _, key := range attrs {
+			fmt.Fprintf(buf, " %s=\"", key)
+			// xml.EscapeText escapes '&', '<', '>', '\'', and '"', so
+			// it is safe to use for attribute values as well as
+			// element text - unlike %q, which applies Go string
+			// escaping and leaves XML metacharacters like '<' intact.
+			if err := xml.EscapeText(buf, []byte(fmt.Sprint(v[key].(xmlAttr).Value))); err != nil {
+				return err
+			}
+			buf.WriteString("\"")
+		}
+		buf.WriteString(">")
+		for _, key := range children {
+			if err := writeXMLElement(buf, key, v[key]); err != nil {
+				return err
+			}
+		}
+		fmt.Fprintf(buf, "</%s>", name)
+		return nil
+	case []interface{}:
+		for _, element := range v {
+			if err := writeXMLElement(buf, name, element); err != nil {
+				return err
+			}
+		}
+		return nil
+	case nil:
+		fmt.Fprintf(buf, "<%s/>", name)
+		return nil
+	default:
+		fmt.Fprintf(buf, "<%s>", name)
+		xml.EscapeText(buf, []byte(fmt.Sprint(v)))
+		fmt.Fprintf(buf, "</%s>", name)
+		return nil
+	}
+}
+
+// splitXMLFields separates a response map's keys into those tagged as
+// XML attributes and those that should be written as child elements,
+// each sorted for deterministic output.
+func splitXMLFields(m objx.Map) (attrs, children []string) {
+	for key, val := range m {
+		if _, ok := val.(xmlAttr); ok {
+			attrs = append(attrs, key)
+		} else {
+			children = append(children, key)
+		}
+	}
+	sort.Strings(attrs)
+	sort.Strings(children)
+	return attrs, children
+}
+
+// ResponseWriter dispatches a Response to the right Encoder based on
+// an incoming request's Accept header, so a service can expose the
+// same handlers as JSON to browsers and msgpack/XML to internal
+// clients without maintaining parallel struct definitions.
+type ResponseWriter struct {
+	// Encoders is consulted, in order, against the request's Accept
+	// header. The first Encoder whose ContentType appears in Accept is
+	// used; if none match (or Accept is empty or "*/*"), the first
+	// Encoder is used as the default.
+	Encoders []Encoder
+}
+
+// DefaultResponseWriter offers JSON, XML, and MessagePack, with JSON
+// as the default when no Accept header matches.
+var DefaultResponseWriter = &ResponseWriter{
+	Encoders: []Encoder{JSONEncoder{}, XMLEncoder{}, MsgpackEncoder{}},
+}
+
+// WriteResponse encodes resp using the Encoder selected by r's Accept
+// header and writes it to w, setting the Content-Type header to
+// match.
+func (writer *ResponseWriter) WriteResponse(w http.ResponseWriter, r *http.Request, resp *Response) error {
+	encoder := writer.selectEncoder(r.Header.Get("Accept"))
+
+	out, err := encoder.Encode(resp)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", encoder.ContentType())
+	_, err = w.Write(out)
+	return err
+}
+
+func (writer *ResponseWriter) selectEncoder(accept string) Encoder {
+	if accept != "" && accept != "*/*" {
+		for _, encoder := range writer.Encoders {
+			if strings.Contains(accept, encoder.ContentType()) {
+				return encoder
+			}
+		}
+	}
+	return writer.Encoders[0]
+}
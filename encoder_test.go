@@ -0,0 +1,72 @@
+package web_responders
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type encThing struct {
+	ID string `response:"id" xml:"id,attr"`
+}
+
+func TestXMLEncoderEscapesAttributeValues(t *testing.T) {
+	malicious := `1"><script>alert(1)</script>`
+
+	out, err := (XMLEncoder{}).Encode(&Response{Data: encThing{ID: malicious}})
+	if err != nil {
+		t.Fatalf("Encode returned an error: %v", err)
+	}
+
+	body := string(out)
+	if strings.Contains(body, "<script>") {
+		t.Fatalf("attribute value broke out of its quotes and injected markup: %s", body)
+	}
+	if !strings.Contains(body, `id="1&#34;&gt;&lt;script&gt;alert(1)&lt;/script&gt;"`) {
+		t.Errorf("expected the attribute value to be XML-escaped, got: %s", body)
+	}
+}
+
+func TestXMLEncoderWrapsTopLevelListInSingleRoot(t *testing.T) {
+	things := []encThing{{ID: "1"}, {ID: "2"}}
+
+	out, err := (XMLEncoder{}).Encode(&Response{Data: things})
+	if err != nil {
+		t.Fatalf("Encode returned an error: %v", err)
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"response"`
+		Items   []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"item"`
+	}
+	if err := xml.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("expected well-formed XML with a single root element, got an unmarshal error: %v\nbody: %s", err, out)
+	}
+
+	if len(doc.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d: %s", len(doc.Items), out)
+	}
+	if doc.Items[0].ID != "1" || doc.Items[1].ID != "2" {
+		t.Errorf("expected items with IDs \"1\" and \"2\", got %+v", doc.Items)
+	}
+}
+
+func TestMsgpackEncoderUnwrapsXMLAttrsWithoutASecondTreeWalk(t *testing.T) {
+	out, err := (MsgpackEncoder{}).Encode(&Response{Data: encThing{ID: "1"}})
+	if err != nil {
+		t.Fatalf("Encode returned an error: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := msgpack.Unmarshal(out, &decoded); err != nil {
+		t.Fatalf("expected msgpack.Unmarshal to decode a plain string for the id field, got an error: %v", err)
+	}
+
+	if decoded["id"] != "1" {
+		t.Errorf(`expected decoded["id"] to be the bare string "1", got %#v`, decoded["id"])
+	}
+}
@@ -0,0 +1,115 @@
+package web_responders
+
+import (
+	"net/url"
+	"strings"
+)
+
+// LinkResponder is implemented by relation values that can provide a
+// stub representation of themselves - typically a link or an ID map -
+// for use when a FieldSelector's include-tree does not request that
+// relation be expanded.
+type LinkResponder interface {
+	// LinkResponseData should return the stub data to use in place of
+	// the fully-populated response when this value is excluded by an
+	// include-tree.
+	LinkResponseData() interface{}
+}
+
+// IncludeTree is a parsed representation of a JSON:API-style "include"
+// query parameter, such as "include=posts.author".  Each key is the
+// response name of a relation that should be expanded; its value is the
+// subtree of relations to expand beneath it.
+type IncludeTree map[string]IncludeTree
+
+// FieldSelector holds a client-supplied sparse fieldset and
+// include-tree, as parsed from JSON:API-style "fields[type]" and
+// "include" query parameters.  A Response consults its FieldSelector,
+// if any, while generating output, so that only the requested fields
+// and relations are serialized.
+type FieldSelector struct {
+	// Fields maps a lowercased resource type name to the set of
+	// response field names that should be included for that type.  A
+	// type with no entry here is not restricted, and all of its fields
+	// are included.  Lookups against this map are case-insensitive;
+	// see fieldAllowed.
+	Fields map[string]map[string]bool
+
+	// Include is the parsed include-tree.  A relation whose response
+	// name is not present in the tree will be replaced with stub data
+	// from LinkResponder, if the relation's value implements it.
+	Include IncludeTree
+}
+
+// fieldAllowed reports whether the named field of typeName should be
+// included in the response, based on the selector's per-type
+// allowlist.  typeName is matched case-insensitively, since JSON:API
+// clients conventionally lowercase resource type names (e.g.
+// "fields[user]") while the matching Go struct name is capitalized
+// (e.g. "User").
+func (selector *FieldSelector) fieldAllowed(typeName, name string) bool {
+	if selector == nil {
+		return true
+	}
+	allowed, restricted := selector.Fields[strings.ToLower(typeName)]
+	if !restricted {
+		return true
+	}
+	return allowed[name]
+}
+
+// ParseFieldSelector builds a FieldSelector from a URL query string
+// containing JSON:API-style "fields[type]" and "include" parameters.
+// For example:
+//
+//	fields[user]=name,email&include=posts.author
+//
+// will restrict the "user" type to the "name" and "email" fields, and
+// will expand the "posts" relation and the "author" relation nested
+// beneath it.
+func ParseFieldSelector(values url.Values) *FieldSelector {
+	selector := &FieldSelector{
+		Fields:  make(map[string]map[string]bool),
+		Include: make(IncludeTree),
+	}
+
+	for key, vals := range values {
+		if !strings.HasPrefix(key, "fields[") || !strings.HasSuffix(key, "]") {
+			continue
+		}
+		typeName := strings.ToLower(key[len("fields[") : len(key)-1])
+		names := make(map[string]bool)
+		for _, val := range vals {
+			for _, name := range strings.Split(val, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					names[name] = true
+				}
+			}
+		}
+		selector.Fields[typeName] = names
+	}
+
+	for _, val := range values["include"] {
+		for _, path := range strings.Split(val, ",") {
+			if path = strings.TrimSpace(path); path == "" {
+				continue
+			}
+			selector.Include.addPath(strings.Split(path, "."))
+		}
+	}
+
+	return selector
+}
+
+func (tree IncludeTree) addPath(parts []string) {
+	if len(parts) == 0 {
+		return
+	}
+	name := parts[0]
+	child, ok := tree[name]
+	if !ok {
+		child = make(IncludeTree)
+		tree[name] = child
+	}
+	child.addPath(parts[1:])
+}
@@ -0,0 +1,81 @@
+package web_responders
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/objx"
+)
+
+type fsUser struct {
+	Name  string `response:"name"`
+	Email string `response:"email"`
+	Age   int    `response:"age"`
+}
+
+func TestFieldSelectorRestrictsFields(t *testing.T) {
+	selector := ParseFieldSelector(url.Values{"fields[user]": []string{"name,email"}})
+
+	response := &Response{
+		Data:          fsUser{Name: "Ada", Email: "ada@example.com", Age: 30},
+		FieldSelector: selector,
+	}
+
+	out, ok := response.Output().(objx.Map)
+	if !ok {
+		t.Fatalf("expected objx.Map output, got %T", response.Output())
+	}
+
+	if _, present := out["age"]; present {
+		t.Errorf(`expected "age" to be dropped by the sparse fieldset, got %v`, out)
+	}
+	if out["name"] != "Ada" {
+		t.Errorf(`expected "name" to survive the sparse fieldset, got %v`, out["name"])
+	}
+	if out["email"] != "ada@example.com" {
+		t.Errorf(`expected "email" to survive the sparse fieldset, got %v`, out["email"])
+	}
+}
+
+type fsPost struct {
+	ID int `response:"id"`
+}
+
+func (p fsPost) LinkResponseData() interface{} {
+	return objx.Map{"id": p.ID}
+}
+
+type fsAuthor struct {
+	Posts []fsPost `response:"posts"`
+}
+
+func TestFieldSelectorStubsToManyRelationsNotIncluded(t *testing.T) {
+	selector := &FieldSelector{Include: IncludeTree{}}
+
+	response := &Response{
+		Data:          fsAuthor{Posts: []fsPost{{ID: 1}, {ID: 2}}},
+		FieldSelector: selector,
+	}
+
+	out, ok := response.Output().(objx.Map)
+	if !ok {
+		t.Fatalf("expected objx.Map output, got %T", response.Output())
+	}
+
+	posts, ok := out["posts"].([]interface{})
+	if !ok {
+		t.Fatalf(`expected "posts" to be a stubbed slice, got %T`, out["posts"])
+	}
+	if len(posts) != 2 {
+		t.Fatalf("expected 2 stubbed posts, got %d", len(posts))
+	}
+	for i, post := range posts {
+		stub, ok := post.(objx.Map)
+		if !ok {
+			t.Fatalf("expected stub at index %d to be an objx.Map, got %T", i, post)
+		}
+		if len(stub) != 1 {
+			t.Errorf("expected stub at index %d to only contain LinkResponseData, got %v", i, stub)
+		}
+	}
+}
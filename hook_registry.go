@@ -0,0 +1,119 @@
+package web_responders
+
+import (
+	"encoding/base64"
+	"reflect"
+	"time"
+
+	"github.com/stretchr/objx"
+)
+
+// Hook is a function that customizes marshalling for a value, in the
+// same spirit as mapstructure's DecodeHookFunc. It is passed the value
+// being converted and the Response's Options, and should return
+// (output, true) if it handled the value.  Returning (nil, false)
+// leaves the value for later hooks, or for the standard reflect walk,
+// to handle.
+//
+// Unlike Constructor, a Hook's output is used verbatim - the response
+// does not descend into it afterward.
+type Hook func(v reflect.Value, opts objx.Map) (interface{}, bool)
+
+// HookRegistry maps reflect.Types and reflect.Kinds to Hooks, so that
+// marshalling can be customized for types - especially third-party
+// types, like time.Time, decimal.Decimal, or uuid.UUID - that can't
+// implement ResponseConverter or ResponseElementConverter themselves.
+//
+// createResponse consults a HookRegistry before falling into its
+// reflect-based walk of the value.  Type hooks are tried before kind
+// hooks, and hooks within each are tried in registration order; the
+// first hook to report success wins.
+type HookRegistry struct {
+	typeHooks map[reflect.Type][]Hook
+	kindHooks map[reflect.Kind][]Hook
+}
+
+// NewHookRegistry returns an empty HookRegistry, ready to have hooks
+// registered on it.
+func NewHookRegistry() *HookRegistry {
+	return &HookRegistry{
+		typeHooks: make(map[reflect.Type][]Hook),
+		kindHooks: make(map[reflect.Kind][]Hook),
+	}
+}
+
+// DefaultHooks is the package-level HookRegistry used by any Response
+// whose own HookRegistry field is nil.
+var DefaultHooks = NewHookRegistry()
+
+func init() {
+	DefaultHooks.RegisterType(reflect.TypeOf(time.Time{}), TimeHook)
+	DefaultHooks.RegisterType(reflect.TypeOf([]byte(nil)), ByteSliceHook)
+	DefaultHooks.RegisterKind(reflect.Struct, NullableDbHook)
+}
+
+// RegisterType adds hook as a Hook for the exact type t.  Type hooks
+// run before any hooks registered for t's Kind.
+func (registry *HookRegistry) RegisterType(t reflect.Type, hook Hook) {
+	registry.typeHooks[t] = append(registry.typeHooks[t], hook)
+}
+
+// RegisterKind adds hook as a Hook for the given reflect.Kind.  Kind
+// hooks run after any hooks registered for the value's exact type.
+func (registry *HookRegistry) RegisterKind(kind reflect.Kind, hook Hook) {
+	registry.kindHooks[kind] = append(registry.kindHooks[kind], hook)
+}
+
+// run tries, in order, the type hooks registered for v's exact type
+// and then the kind hooks registered for v's Kind, returning the
+// output of the first hook that reports success.
+func (registry *HookRegistry) run(v reflect.Value, opts objx.Map) (interface{}, bool) {
+	if registry == nil || !v.IsValid() {
+		return nil, false
+	}
+	if out, ok := ComposeHooks(registry.typeHooks[v.Type()]...)(v, opts); ok {
+		return out, true
+	}
+	return ComposeHooks(registry.kindHooks[v.Kind()]...)(v, opts)
+}
+
+// ComposeHooks returns a Hook that tries each of the given hooks in
+// order, returning the output of the first one that reports success.
+func ComposeHooks(hooks ...Hook) Hook {
+	return func(v reflect.Value, opts objx.Map) (interface{}, bool) {
+		for _, hook := range hooks {
+			if out, ok := hook(v, opts); ok {
+				return out, true
+			}
+		}
+		return nil, false
+	}
+}
+
+// TimeHook formats time.Time values as RFC3339 strings.
+func TimeHook(v reflect.Value, opts objx.Map) (interface{}, bool) {
+	t, ok := v.Interface().(time.Time)
+	if !ok {
+		return nil, false
+	}
+	return t.Format(time.RFC3339), true
+}
+
+// ByteSliceHook base64-encodes []byte values.
+func ByteSliceHook(v reflect.Value, opts objx.Map) (interface{}, bool) {
+	b, ok := v.Interface().([]byte)
+	if !ok {
+		return nil, false
+	}
+	return base64.StdEncoding.EncodeToString(b), true
+}
+
+// NullableDbHook handles "database/sql".Null* types, and any other
+// type matching that structure, the same way this package always has.
+func NullableDbHook(v reflect.Value, opts objx.Map) (interface{}, bool) {
+	out, err := createNullableDbResponse(v)
+	if err != nil {
+		return nil, false
+	}
+	return out, true
+}
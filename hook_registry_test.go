@@ -0,0 +1,38 @@
+package web_responders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/objx"
+)
+
+func TestTimeHookRunsBeforeStringerConversion(t *testing.T) {
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	out := (&Response{Data: when}).Output()
+
+	formatted, ok := out.(string)
+	if !ok {
+		t.Fatalf("expected a string, got %T", out)
+	}
+	if formatted != when.Format(time.RFC3339) {
+		t.Errorf("expected RFC3339 time %q, got %q (TimeHook was bypassed by time.Time's fmt.Stringer)", when.Format(time.RFC3339), formatted)
+	}
+}
+
+type hrEvent struct {
+	At time.Time `response:"at"`
+}
+
+func TestTimeHookRunsForNestedFields(t *testing.T) {
+	when := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	out, ok := (&Response{Data: hrEvent{At: when}}).Output().(objx.Map)
+	if !ok {
+		t.Fatalf("expected objx.Map output, got %T", out)
+	}
+	if out["at"] != when.Format(time.RFC3339) {
+		t.Errorf("expected RFC3339 time %q, got %v", when.Format(time.RFC3339), out["at"])
+	}
+}
@@ -12,8 +12,46 @@ package web_responders
 //     func (e *Example) PreMarshal() {
 //         e.data = "Hello!"
 //     }
+//
+// PreMarshal is called once per value in the response, before any
+// conversion happens, so mutations it makes are visible to the rest of
+// the marshalling lifecycle. See the package-level ordering
+// documented on PostMarshaller.
 type PreMarshaller interface {
 	// PreMarshal should do any work on the object that needs to happen
 	// before the response is encoded.
 	PreMarshal()
 }
+
+// PostMarshaller is the per-value counterpart to Fixer: it is used for
+// types that need to adjust the generated output for a single value,
+// after that value has been converted.
+//
+// Example:
+//
+//     type Example struct {
+//         Secret string `response:"-"`
+//     }
+//
+//     func (e *Example) PostMarshal(out interface{}) interface{} {
+//         out.(objx.Map)["redacted"] = e.Secret != ""
+//         return out
+//     }
+//
+// A Response builds each value's output in the following order:
+//
+//  1. LazyLoad, if the value implements LazyLoader
+//  2. PreMarshal, if the value implements PreMarshaller
+//  3. Constructor, if the Response has one
+//  4. ResponseConverter/ResponseElementConverter, if the value
+//     implements either
+//  5. The reflect-based walk (or a matching HookRegistry hook, if one
+//     fires in its place)
+//  6. PostMarshal, if the value implements PostMarshaller
+//  7. Fixer, if the Response has one
+type PostMarshaller interface {
+	// PostMarshal is given the output generated for this value, and
+	// should return the (possibly adjusted) output to use in its
+	// place.
+	PostMarshal(out interface{}) interface{}
+}
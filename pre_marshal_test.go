@@ -0,0 +1,72 @@
+package web_responders
+
+import (
+	"testing"
+
+	"github.com/stretchr/objx"
+)
+
+// pmLifecycle implements LazyLoader, PreMarshaller, and PostMarshaller,
+// recording the order in which Response calls them, so the whole
+// marshalling lifecycle can be asserted against in one place.
+type pmLifecycle struct {
+	order  *[]string
+	marked bool
+}
+
+func (l *pmLifecycle) LazyLoad(opts objx.Map) {
+	*l.order = append(*l.order, "LazyLoad")
+}
+
+func (l *pmLifecycle) PreMarshal() {
+	*l.order = append(*l.order, "PreMarshal")
+	// Mutates state that only PostMarshal (below) observes, to prove
+	// PreMarshal ran against the original *pmLifecycle rather than a
+	// copy.
+	l.marked = true
+}
+
+func (l *pmLifecycle) PostMarshal(out interface{}) interface{} {
+	*l.order = append(*l.order, "PostMarshal")
+	if m, ok := out.(objx.Map); ok {
+		m["marked"] = l.marked
+	}
+	return out
+}
+
+func TestMarshalLifecycleOrder(t *testing.T) {
+	order := []string{}
+	data := &pmLifecycle{order: &order}
+
+	response := &Response{
+		Data: data,
+		Constructor: func(d interface{}, depth int) (interface{}, bool) {
+			order = append(order, "Constructor")
+			return d, true
+		},
+		Fixer: func(out interface{}) interface{} {
+			order = append(order, "Fixer")
+			return out
+		},
+	}
+
+	out := response.Output()
+
+	expected := []string{"LazyLoad", "PreMarshal", "Constructor", "PostMarshal", "Fixer"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected lifecycle order %v, got %v", expected, order)
+	}
+	for i, step := range expected {
+		if order[i] != step {
+			t.Fatalf("expected lifecycle order %v, got %v", expected, order)
+		}
+	}
+
+	m, ok := out.(objx.Map)
+	if !ok {
+		t.Fatalf("expected objx.Map output, got %T", out)
+	}
+	if marked, _ := m["marked"].(bool); !marked {
+		t.Errorf("expected PreMarshal's mutation to be visible by the time PostMarshal ran, got %v", m["marked"])
+	}
+}
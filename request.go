@@ -0,0 +1,381 @@
+package web_responders
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+
+	"github.com/stretchr/objx"
+)
+
+// Metadata reports which keys of a Request's input were consumed
+// during Decode, and which were present in the input but left unused
+// because no matching field was found. Keys for nested structs and
+// maps are dotted, e.g. "address.city".
+type Metadata struct {
+	Keys   []string
+	Unused []string
+}
+
+// A Request is used for decoding a map[string]interface{}-shaped
+// payload - typically parsed from a query string, form body, or JSON
+// request body - into a Go struct, slice, or map. It is the input-side
+// counterpart to Response: both use the same response/json tag
+// precedence via ResponseTag, so a handler can describe its wire
+// format once and use it for both directions.
+type Request struct {
+	// WeaklyTypedInput, if true, allows values to be coerced between
+	// similar Go types - for example, the strings "1" and "true" will
+	// decode into an int and a bool, respectively - instead of
+	// requiring an exact type match. This is particularly useful when
+	// decoding query strings and form bodies, which are always
+	// strings on the wire.
+	WeaklyTypedInput bool
+
+	// Options is passed to any DecodeHook run during Decode, mirroring
+	// Response.Options.
+	Options objx.Map
+
+	// HookRegistry, if set, is consulted before the standard decode
+	// logic for every value in the input. If nil, DefaultDecodeHooks is
+	// used instead. See DecodeHookRegistry.
+	HookRegistry *DecodeHookRegistry
+
+	// Metadata, if non-nil, is populated during Decode with which
+	// input keys were used, and which were present in the input but
+	// didn't correspond to any field on the target.
+	Metadata *Metadata
+}
+
+// Decode populates target, which must be a non-nil pointer, from
+// input. input is typically a map[string]interface{}, as produced by
+// ParseQuery or by decoding a JSON object. A struct or map target takes
+// a map; a slice target takes a slice or array (or, with
+// WeaklyTypedInput, a single value, decoded as a one-element slice); a
+// pointer target is allocated if nil and decoded into; any other target
+// takes a value of an assignable or numeric type, or, with
+// WeaklyTypedInput, a value that can be coerced to it.
+func (request *Request) Decode(input interface{}, target interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return fmt.Errorf("web_responders: Decode target must be a non-nil pointer, got %T", target)
+	}
+
+	if request.Metadata != nil {
+		request.Metadata.Keys = nil
+		request.Metadata.Unused = nil
+	}
+
+	return request.decodeValue("", input, targetValue.Elem())
+}
+
+// ParseQuery converts url.Values, as produced by parsing a query
+// string or an "application/x-www-form-urlencoded" body, into the
+// map[string]interface{} shape Request.Decode expects. A key with a
+// single value decodes to that string; a key with more than one value
+// decodes to a []string.
+func ParseQuery(values url.Values) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for key, vals := range values {
+		if len(vals) == 1 {
+			result[key] = vals[0]
+		} else {
+			result[key] = append([]string(nil), vals...)
+		}
+	}
+	return result
+}
+
+func (request *Request) hooks() *DecodeHookRegistry {
+	if request.HookRegistry != nil {
+		return request.HookRegistry
+	}
+	return DefaultDecodeHooks
+}
+
+// decodeValue decodes input into target. path is the dotted key path
+// to target, used for error messages and Metadata.
+func (request *Request) decodeValue(path string, input interface{}, target reflect.Value) error {
+	if out, ok, err := request.hooks().run(target, input, request.Options); ok || err != nil {
+		if err != nil {
+			return fmt.Errorf("web_responders: decoding %q: %w", path, err)
+		}
+		return request.setValue(target, out)
+	}
+
+	if input == nil {
+		return request.decodeNil(target)
+	}
+
+	switch target.Kind() {
+	case reflect.Ptr:
+		return request.decodePtr(path, input, target)
+	case reflect.Struct:
+		return request.decodeStruct(path, input, target)
+	case reflect.Slice:
+		return request.decodeSlice(path, input, target)
+	case reflect.Map:
+		return request.decodeMap(path, input, target)
+	default:
+		return request.decodeBasic(path, input, target)
+	}
+}
+
+func (request *Request) setValue(target reflect.Value, output interface{}) error {
+	if output == nil {
+		return request.decodeNil(target)
+	}
+	outputValue := reflect.ValueOf(output)
+	if !outputValue.Type().AssignableTo(target.Type()) {
+		if !outputValue.Type().ConvertibleTo(target.Type()) {
+			return fmt.Errorf("web_responders: decode hook returned %T, not assignable to %s", output, target.Type())
+		}
+		outputValue = outputValue.Convert(target.Type())
+	}
+	target.Set(outputValue)
+	return nil
+}
+
+func (request *Request) decodeNil(target reflect.Value) error {
+	if target.Kind() == reflect.Ptr {
+		target.Set(reflect.Zero(target.Type()))
+	}
+	return nil
+}
+
+func (request *Request) decodePtr(path string, input interface{}, target reflect.Value) error {
+	if target.IsNil() {
+		target.Set(reflect.New(target.Type().Elem()))
+	}
+	return request.decodeValue(path, input, target.Elem())
+}
+
+// decodeStruct populates the exported fields of target from inputMap,
+// matching keys via ResponseTag. Anonymous (embedded) fields are
+// flattened: the whole input is decoded into them, so their fields are
+// addressed the same way as the outer struct's.
+// decodeStruct is the entry point for decoding into a struct: it
+// converts input into a map once, walks target's fields - flattening
+// any anonymous fields into that same map - and then, exactly once,
+// reports which of the map's keys were used. Metadata must only be
+// reported here, at the outermost call for a given input map; see
+// decodeStructFields, which anonymous fields recurse through instead
+// of coming back through decodeStruct, so that an embedded struct
+// doesn't independently (and incorrectly) summarize the whole map as
+// if it belonged to that struct alone.
+func (request *Request) decodeStruct(path string, input interface{}, target reflect.Value) error {
+	inputMap, err := toMap(input)
+	if err != nil {
+		return fmt.Errorf("web_responders: decoding %q: %w", path, err)
+	}
+
+	used := make(map[string]bool, len(inputMap))
+	if err := request.decodeStructFields(path, inputMap, target, used); err != nil {
+		return err
+	}
+
+	if request.Metadata != nil {
+		for key := range inputMap {
+			if used[key] {
+				request.Metadata.Keys = append(request.Metadata.Keys, joinPath(path, key))
+			} else {
+				request.Metadata.Unused = append(request.Metadata.Unused, joinPath(path, key))
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeStructFields populates target's exported fields from
+// inputMap, recording each key it consumes in used. An anonymous
+// struct (or pointer-to-struct) field is flattened by recursing with
+// the same inputMap and used accumulator, rather than by going back
+// through decodeStruct, so that the whole tree of embedded fields
+// shares one used map and decodeStruct only reports Metadata once.
+func (request *Request) decodeStructFields(path string, inputMap map[string]interface{}, target reflect.Value, used map[string]bool) error {
+	targetType := target.Type()
+	for i := 0; i < target.NumField(); i++ {
+		fieldType := targetType.Field(i)
+		fieldValue := target.Field(i)
+
+		// Anonymous fields are checked before exported-ness: an embedded
+		// struct of an unexported type is still flattened, since its own
+		// exported fields remain settable.
+		if fieldType.Anonymous {
+			embedded := fieldValue
+			if embedded.Kind() == reflect.Ptr {
+				if embedded.IsNil() {
+					if !embedded.CanSet() {
+						continue
+					}
+					embedded.Set(reflect.New(embedded.Type().Elem()))
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := request.decodeStructFields(path, inputMap, embedded, used); err != nil {
+					return err
+				}
+				continue
+			}
+			if fieldType.PkgPath != "" {
+				continue
+			}
+			// Not a struct, or a pointer to one: there's no flattening
+			// to do, so decode the whole map into it directly.
+			if err := request.decodeValue(path, inputMap, fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if fieldType.PkgPath != "" {
+			continue
+		}
+
+		name := ResponseTag(fieldType)
+		if name == "-" {
+			continue
+		}
+
+		value, ok := inputMap[name]
+		if !ok {
+			continue
+		}
+		used[name] = true
+
+		if err := request.decodeValue(joinPath(path, name), value, fieldValue); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (request *Request) decodeSlice(path string, input interface{}, target reflect.Value) error {
+	inputValue := reflect.ValueOf(input)
+	if inputValue.Kind() != reflect.Slice && inputValue.Kind() != reflect.Array {
+		if !request.WeaklyTypedInput {
+			return fmt.Errorf("web_responders: decoding %q: cannot decode %T into %s", path, input, target.Type())
+		}
+		inputValue = reflect.ValueOf([]interface{}{input})
+	}
+
+	result := reflect.MakeSlice(target.Type(), inputValue.Len(), inputValue.Len())
+	for i := 0; i < inputValue.Len(); i++ {
+		elemPath := fmt.Sprintf("%s[%d]", path, i)
+		if err := request.decodeValue(elemPath, inputValue.Index(i).Interface(), result.Index(i)); err != nil {
+			return err
+		}
+	}
+	target.Set(result)
+	return nil
+}
+
+func (request *Request) decodeMap(path string, input interface{}, target reflect.Value) error {
+	inputMap, err := toMap(input)
+	if err != nil {
+		return fmt.Errorf("web_responders: decoding %q: %w", path, err)
+	}
+
+	result := reflect.MakeMapWithSize(target.Type(), len(inputMap))
+	elemType := target.Type().Elem()
+	for key, value := range inputMap {
+		elemValue := reflect.New(elemType).Elem()
+		if err := request.decodeValue(joinPath(path, key), value, elemValue); err != nil {
+			return err
+		}
+		result.SetMapIndex(reflect.ValueOf(key), elemValue)
+	}
+	target.Set(result)
+	return nil
+}
+
+func (request *Request) decodeBasic(path string, input interface{}, target reflect.Value) error {
+	inputValue := reflect.ValueOf(input)
+	inputKind := inputValue.Kind()
+	targetKind := target.Kind()
+
+	if inputValue.Type().AssignableTo(target.Type()) {
+		target.Set(inputValue)
+		return nil
+	}
+
+	if isNumericKind(inputKind) && isNumericKind(targetKind) {
+		target.Set(inputValue.Convert(target.Type()))
+		return nil
+	}
+
+	if !request.WeaklyTypedInput {
+		return fmt.Errorf("web_responders: decoding %q: cannot decode %T into %s", path, input, target.Type())
+	}
+
+	switch {
+	case targetKind == reflect.String:
+		target.SetString(fmt.Sprintf("%v", input))
+		return nil
+	case targetKind == reflect.Bool && inputKind == reflect.String:
+		b, err := strconv.ParseBool(inputValue.String())
+		if err != nil {
+			return fmt.Errorf("web_responders: decoding %q: cannot decode %q into bool", path, inputValue.String())
+		}
+		target.SetBool(b)
+		return nil
+	case targetKind == reflect.Bool && isNumericKind(inputKind):
+		target.SetBool(inputValue.Convert(reflect.TypeOf(float64(0))).Float() != 0)
+		return nil
+	case isNumericKind(targetKind) && inputKind == reflect.String:
+		f, err := strconv.ParseFloat(inputValue.String(), 64)
+		if err != nil {
+			return fmt.Errorf("web_responders: decoding %q: cannot decode %q into %s", path, inputValue.String(), target.Type())
+		}
+		target.Set(reflect.ValueOf(f).Convert(target.Type()))
+		return nil
+	}
+
+	return fmt.Errorf("web_responders: decoding %q: cannot decode %T into %s", path, input, target.Type())
+}
+
+func isNumericKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// toMap converts input, which must be a map keyed by string (or
+// something convertible to one), into a map[string]interface{}.
+func toMap(input interface{}) (map[string]interface{}, error) {
+	switch m := input.(type) {
+	case map[string]interface{}:
+		return m, nil
+	case objx.Map:
+		return m, nil
+	}
+
+	value := reflect.ValueOf(input)
+	for value.Kind() == reflect.Ptr {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Map {
+		return nil, fmt.Errorf("expected a map, got %T", input)
+	}
+
+	result := make(map[string]interface{}, value.Len())
+	for _, key := range value.MapKeys() {
+		result[fmt.Sprintf("%v", key.Interface())] = value.MapIndex(key).Interface()
+	}
+	return result, nil
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,73 @@
+package web_responders
+
+import (
+	"testing"
+	"time"
+)
+
+type rtEvent struct {
+	Name string    `response:"name"`
+	At   time.Time `response:"at"`
+}
+
+func TestTimeRoundTripsThroughResponseAndRequest(t *testing.T) {
+	original := rtEvent{Name: "launch", At: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)}
+
+	encoded := (&Response{Data: original}).Output()
+
+	var decoded rtEvent
+	if err := (&Request{}).Decode(encoded, &decoded); err != nil {
+		t.Fatalf("Decode returned an error round-tripping the encoder's own output: %v", err)
+	}
+
+	if !decoded.At.Equal(original.At) {
+		t.Errorf("expected decoded time %v, got %v", original.At, decoded.At)
+	}
+	if decoded.Name != original.Name {
+		t.Errorf("expected decoded name %q, got %q", original.Name, decoded.Name)
+	}
+}
+
+type rtEmbedded struct {
+	ID string `response:"id"`
+}
+
+type rtOuter struct {
+	rtEmbedded
+	Name string `response:"name"`
+}
+
+func TestDecodeMetadataDoesNotDoubleCountEmbeddedFields(t *testing.T) {
+	input := map[string]interface{}{
+		"id":   "5",
+		"name": "x",
+	}
+
+	var decoded rtOuter
+	metadata := &Metadata{}
+	request := &Request{Metadata: metadata}
+	if err := request.Decode(input, &decoded); err != nil {
+		t.Fatalf("Decode returned an error: %v", err)
+	}
+
+	if decoded.ID != "5" || decoded.Name != "x" {
+		t.Fatalf("expected ID %q and Name %q, got ID %q and Name %q", "5", "x", decoded.ID, decoded.Name)
+	}
+
+	seen := make(map[string]int)
+	for _, key := range metadata.Keys {
+		seen[key]++
+	}
+	for _, key := range metadata.Unused {
+		seen[key]++
+	}
+
+	for _, key := range []string{"id", "name"} {
+		if seen[key] != 1 {
+			t.Errorf("expected %q to be reported exactly once across Keys/Unused, got %d times (Keys=%v Unused=%v)", key, seen[key], metadata.Keys, metadata.Unused)
+		}
+	}
+	for _, key := range metadata.Unused {
+		t.Errorf("expected no unused keys, but %q was reported unused", key)
+	}
+}
@@ -61,7 +61,25 @@ type Response struct {
 	Fixer       Fixer
 	Options     objx.Map
 
+	// FieldSelector, if set, restricts the fields and relations that
+	// are serialized, based on a client-supplied sparse fieldset and
+	// include-tree.  See FieldSelector and ParseFieldSelector.
+	FieldSelector *FieldSelector
+
+	// HookRegistry, if set, is consulted before the standard reflect
+	// walk for every value in the response, giving it a chance to
+	// supply the output for types that can't implement the converter
+	// interfaces directly.  If nil, DefaultHooks is used instead.  See
+	// HookRegistry.
+	HookRegistry *HookRegistry
+
 	output interface{}
+
+	// currentInclude tracks the subtree of FieldSelector.Include that
+	// applies at the current point in the traversal.  It starts out as
+	// FieldSelector.Include and is narrowed as the traversal descends
+	// into included relations.
+	currentInclude IncludeTree
 }
 
 // Output generates and returns the proper output structure for
@@ -88,6 +106,22 @@ type Response struct {
 //
 // A value of "-" for the "response" tag of a field will result in
 // that field being skipped.
+//
+// Scope cut: Output() always materializes the whole tree - every
+// struct/slice/map in Data is walked and converted into objx.Map,
+// []interface{}, and scalars before any encoding happens, rather than
+// emitting directly to a target format as the walk proceeds. This is a
+// deliberate tradeoff, not an oversight: tag lookup, embedded
+// flattening, FieldSelector filtering, HookRegistry, and
+// Constructor/Fixer all need to run as one shared traversal so JSON,
+// XML, and MessagePack output stay identical, and the simplest way to
+// guarantee that is to finish producing one tree and hand it to
+// encoding/json, encoding/xml, and msgpack in turn. A true
+// zero-intermediate-allocation encoder would need each of those steps
+// to write through a format-specific sink instead of returning a value,
+// which is a larger rework of this package than comes with any single
+// Encoder. See Encoder for how the three current encoders share this
+// tree.
 func (response *Response) Output() interface{} {
 	if response.output == nil {
 		response.output = response.createOutput()
@@ -95,11 +129,24 @@ func (response *Response) Output() interface{} {
 	return response.output
 }
 
+// hooks returns the HookRegistry this response should consult: its own
+// HookRegistry, if set, or DefaultHooks otherwise.
+func (response *Response) hooks() *HookRegistry {
+	if response.HookRegistry != nil {
+		return response.HookRegistry
+	}
+	return DefaultHooks
+}
+
 func (response *Response) createOutput() interface{} {
 	if err, ok := response.Data.(error); ok {
 		return err.Error()
 	}
 
+	if response.FieldSelector != nil {
+		response.currentInclude = response.FieldSelector.Include
+	}
+
 	return response.createResponse(response.Data, 0)
 }
 
@@ -108,6 +155,10 @@ func (response *Response) createResponse(data interface{}, depth int) interface{
 		lazyLoader.LazyLoad(response.Options)
 	}
 
+	if preMarshaller, ok := data.(PreMarshaller); ok {
+		preMarshaller.PreMarshal()
+	}
+
 	responseData := data
 	if response.Constructor != nil {
 		var descend bool
@@ -121,6 +172,15 @@ func (response *Response) createResponse(data interface{}, depth int) interface{
 		responseData = converter.ResponseData()
 	}
 
+	// The HookRegistry is consulted on the value as-is, before the
+	// fmt.Stringer/error special-casing below, so that a hook
+	// registered for a type that happens to implement one of those
+	// interfaces - time.Time, most notably - still sees the original
+	// value instead of its already-stringified form.
+	if out, ok := response.hooks().run(derefValue(responseData), response.Options); ok {
+		return response.finishResponse(data, out)
+	}
+
 	switch source := responseData.(type) {
 	case fmt.Stringer:
 		responseData = source.String()
@@ -128,10 +188,7 @@ func (response *Response) createResponse(data interface{}, depth int) interface{
 		responseData = source.Error()
 	}
 
-	value := reflect.ValueOf(responseData)
-	for value.Kind() == reflect.Ptr && !value.IsNil() {
-		value = value.Elem()
-	}
+	value := derefValue(responseData)
 
 	switch value.Kind() {
 	case reflect.Ptr:
@@ -146,10 +203,29 @@ func (response *Response) createResponse(data interface{}, depth int) interface{
 		responseData = response.createMapResponse(value, depth)
 	}
 
+	return response.finishResponse(data, responseData)
+}
+
+// derefValue returns the reflect.Value of data, following pointers
+// down to the first non-pointer or nil value.
+func derefValue(data interface{}) reflect.Value {
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	return value
+}
+
+// finishResponse runs the tail end of a value's marshalling lifecycle:
+// PostMarshal, if data implements PostMarshaller, followed by the
+// Response's Fixer, if any.
+func (response *Response) finishResponse(data interface{}, responseData interface{}) interface{} {
+	if postMarshaller, ok := data.(PostMarshaller); ok {
+		responseData = postMarshaller.PostMarshal(responseData)
+	}
 	if response.Fixer != nil {
 		responseData = response.Fixer(responseData)
 	}
-
 	return responseData
 }
 
@@ -251,6 +327,10 @@ func (response *Response) createStructResponse(value reflect.Value, depth int) i
 			continue
 		default:
 
+			if !response.FieldSelector.fieldAllowed(value.Type().Name(), name) {
+				continue
+			}
+
 			cond := fieldType.Tag.Get("cond")
 			shouldInclude := false
 			condParts := strings.Split(cond, ",")
@@ -280,12 +360,80 @@ func (response *Response) createStructResponse(value reflect.Value, depth int) i
 				}
 				fieldValue = getterMethod.Func.Call([]reflect.Value{receiver})[0]
 			}
-			respMap[name] = response.createResponseValue(fieldValue, depth+1)
+			fieldResponse := response.createIncludedValue(name, fieldValue, depth+1)
+			if isXMLAttr(fieldType) {
+				fieldResponse = xmlAttr{fieldResponse}
+			}
+			respMap[name] = fieldResponse
 		}
 	}
 	return respMap
 }
 
+// createIncludedValue is a helper for generating the response value of
+// a named struct field, honoring the Response's FieldSelector, if any.
+// If the field's value - or, for a to-many relation, every element of
+// it - implements LinkResponder, and the field's name is not present
+// in the active include-tree, the relation is not expanded and
+// LinkResponseData() is used in its place.  Otherwise, the value is
+// generated normally, narrowing the include-tree to the subtree below
+// this field for the duration of the descent.
+func (response *Response) createIncludedValue(name string, fieldValue reflect.Value, depth int) interface{} {
+	if response.FieldSelector == nil {
+		return response.createResponseValue(fieldValue, depth)
+	}
+
+	subtree, included := response.currentInclude[name]
+	if !included {
+		if stub, ok := linkStub(fieldValue); ok {
+			return stub
+		}
+	}
+
+	previousInclude := response.currentInclude
+	response.currentInclude = subtree
+	value := response.createResponseValue(fieldValue, depth)
+	response.currentInclude = previousInclude
+	return value
+}
+
+// linkStub returns the stub representation of fieldValue, for use when
+// an include-tree excludes it: if fieldValue implements LinkResponder,
+// its LinkResponseData(); if fieldValue is a slice or array, the
+// per-element stub of each of its elements, as long as every element
+// implements LinkResponder. It reports false if no stub could be
+// produced, meaning the value should be descended into normally
+// instead.
+func linkStub(fieldValue reflect.Value) (interface{}, bool) {
+	if !fieldValue.IsValid() {
+		return nil, false
+	}
+
+	if fieldValue.Kind() == reflect.Slice || fieldValue.Kind() == reflect.Array {
+		if fieldValue.Kind() == reflect.Slice && fieldValue.IsNil() {
+			return nil, false
+		}
+		stubs := make([]interface{}, fieldValue.Len())
+		for i := 0; i < fieldValue.Len(); i++ {
+			stub, ok := linkStub(fieldValue.Index(i))
+			if !ok {
+				return nil, false
+			}
+			stubs[i] = stub
+		}
+		return stubs, true
+	}
+
+	if !fieldValue.CanInterface() {
+		return nil, false
+	}
+	linkResponder, ok := fieldValue.Interface().(LinkResponder)
+	if !ok {
+		return nil, false
+	}
+	return linkResponder.LinkResponseData(), true
+}
+
 // createResponseValue is a helper for generating responses from
 // sub-elements of a response.
 func (response *Response) createResponseValue(value reflect.Value, depth int) interface{} {